@@ -0,0 +1,61 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// provisionTotal and deleteTotal are backend-agnostic: they count Provision/Delete calls
+// regardless of which VolumeBackend handled them. Per-PV capacity/usage gauges are NFS
+// directory statfs samples and live in pkg/backend, which is the only thing that knows
+// how to collect them.
+var (
+	provisionTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nfs_provisioner_provision_total",
+		Help: "Total number of Provision calls, by result.",
+	}, []string{"result"})
+
+	deleteTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "nfs_provisioner_delete_total",
+		Help: "Total number of Delete calls, by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	prometheus.MustRegister(provisionTotal, deleteTotal)
+}
+
+// serveMetrics starts an HTTP server exposing /metrics in the background. A nil/empty
+// addr disables it.
+func serveMetrics(addr string) {
+	if addr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		glog.Infof("serving metrics on %s/metrics", addr)
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			glog.Errorf("metrics server exited: %v", err)
+		}
+	}()
+}
@@ -17,99 +17,95 @@ limitations under the License.
 package main
 
 import (
-	"errors"
 	"flag"
 	"fmt"
-	"net/url"
 	"os"
-	"os/exec"
-	"path"
-	"path/filepath"
+	"time"
 
-	linuxproc "github.com/c9s/goprocinfo/linux"
 	"github.com/golang/glog"
 	"github.com/kubernetes-incubator/external-storage/lib/controller"
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/backend"
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/nodeagent"
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/snapshot"
 	"k8s.io/api/core/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 )
 
 const (
 	provisionerNameKey = "PROVISIONER_NAME"
 )
 
+// nfsProvisioner dispatches Provision/Delete onto the VolumeBackend selected by each
+// StorageClass's "backend" parameter, defaulting to NFS so existing StorageClasses that
+// don't set it keep behaving exactly as before backends became pluggable.
 type nfsProvisioner struct {
-	client kubernetes.Interface
+	client   kubernetes.Interface
+	events   record.EventRecorder
+	backends map[string]backend.VolumeBackend
 }
 
-const (
-	mountPath = "/persistentvolumes"
-)
-
-var _ controller.Provisioner = &nfsProvisioner{}
-
-func inMap(key string, m map[string]string) bool {
-	_, ok := m[key]
-	return ok
+// newEventRecorder builds the EventRecorder used to surface reclaim actions on PVs.
+func newEventRecorder(client kubernetes.Interface) record.EventRecorder {
+	broadcaster := record.NewBroadcaster()
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: client.CoreV1().Events("")})
+	return broadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "nfs-client-provisioner"})
 }
 
-func isMounted(mp string) bool {
-	mps, err := linuxproc.ReadMounts("/proc/mounts")
-	if err != nil {
-		return false
-	}
-	for _, m := range mps.Mounts {
-		if m.MountPoint == mp {
-			return true
-		}
+// event records a Kubernetes event on volume if an EventRecorder is configured.
+func (p *nfsProvisioner) event(volume *v1.PersistentVolume, eventtype string, reason string, messageFmt string, args ...interface{}) {
+	if p.events == nil {
+		return
 	}
-	return false
-}
-
-func pvName(tenant string, stack string, service string, name string) string {
-	return fmt.Sprintf("%s-%s-%s-%s", tenant, stack, service, name)
+	p.events.Eventf(volume, eventtype, reason, messageFmt, args...)
 }
 
-func mountPoint(server string, path string) string {
-	return fmt.Sprintf("%s/%s/%s", mountPath, server, url.QueryEscape(path))
-}
+var _ controller.Provisioner = &nfsProvisioner{}
 
-func ensureMount(server string, path string) (string, error) {
-	mp := mountPoint(server, path)
-	if isMounted(mp) {
-		return mp, nil
-	}
-	if err := os.MkdirAll(mp, 0777); err != nil {
-		return mp, err
+func (p *nfsProvisioner) backendFor(name string) (backend.VolumeBackend, error) {
+	b, ok := p.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q", name)
 	}
-	// has to be deployed as priviliged container
-	cmd := exec.Command("mount", fmt.Sprintf("%s:%s", server, path), mp)
-	return mp, cmd.Run()
+	return b, nil
 }
 
-func (p *nfsProvisioner) Provision(options controller.VolumeOptions) (*v1.PersistentVolume, error) {
+func (p *nfsProvisioner) Provision(options controller.VolumeOptions) (pv *v1.PersistentVolume, err error) {
+	defer func() {
+		if err != nil {
+			provisionTotal.WithLabelValues("failure").Inc()
+		} else {
+			provisionTotal.WithLabelValues("success").Inc()
+		}
+	}()
 	if options.PVC.Spec.Selector != nil {
 		return nil, fmt.Errorf("claim Selector is not supported")
 	}
 	glog.V(4).Infof("nfs provisioner: VolumeOptions %v", options)
-	params := options.Parameters
-	if !(inMap("nfsPath", params) && inMap("nfsServer", params)) {
-		return nil, fmt.Errorf("nfsPath and nfsServer parameters required")
+
+	backendName := backend.NameFor(options.Parameters)
+	b, err := p.backendFor(backendName)
+	if err != nil {
+		return nil, err
 	}
-	server := params["nfsServer"]
-	path := params["nfsPath"]
-	mp, err := ensureMount(server, path)
+	pvName := backend.DirName(options.Tenant, options.Stack, options.Service, options.PVName)
+
+	source, backendAnnotations, err := b.Ensure(options, pvName)
 	if err != nil {
-		return nil, fmt.Errorf("unable to mount NFS volume: " + err.Error())
+		return nil, err
 	}
-	pvName := pvName(options.Tenant, options.Stack, options.Service, options.PVName)
-	if err := os.MkdirAll(filepath.Join(mp, pvName), 0777); err != nil {
-		return nil, errors.New("unable to create directory to provision new pv: " + err.Error())
+	annotations := map[string]string{backend.NameAnnotation: backendName}
+	for k, v := range backendAnnotations {
+		annotations[k] = v
 	}
 
-	pv := &v1.PersistentVolume{
+	pv = &v1.PersistentVolume{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: options.PVName,
 			Labels: map[string]string{
@@ -117,6 +113,7 @@ func (p *nfsProvisioner) Provision(options controller.VolumeOptions) (*v1.Persis
 				"io.wise2c.stack":   options.Stack,
 				"io.wise2c.service": options.Service,
 			},
+			Annotations: annotations,
 		},
 		Spec: v1.PersistentVolumeSpec{
 			PersistentVolumeReclaimPolicy: options.PersistentVolumeReclaimPolicy,
@@ -124,45 +121,66 @@ func (p *nfsProvisioner) Provision(options controller.VolumeOptions) (*v1.Persis
 			Capacity: v1.ResourceList{
 				v1.ResourceName(v1.ResourceStorage): options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)],
 			},
-			PersistentVolumeSource: v1.PersistentVolumeSource{
-				NFS: &v1.NFSVolumeSource{
-					Server:   server,
-					Path:     filepath.Join(path, pvName),
-					ReadOnly: false,
-				},
-			},
+			PersistentVolumeSource: source,
 		},
 	}
 	return pv, nil
 }
 
-func (p *nfsProvisioner) Delete(volume *v1.PersistentVolume) error {
-	server := volume.Spec.PersistentVolumeSource.NFS.Server
-	// Path include the dynamic volume name
-	path := path.Dir(volume.Spec.PersistentVolumeSource.NFS.Path)
-	mp, err := ensureMount(server, path)
+func (p *nfsProvisioner) Delete(volume *v1.PersistentVolume) (err error) {
+	defer func() {
+		if err != nil {
+			deleteTotal.WithLabelValues("failure").Inc()
+		} else {
+			deleteTotal.WithLabelValues("success").Inc()
+		}
+	}()
+	backendName := volume.Annotations[backend.NameAnnotation]
+	if backendName == "" {
+		backendName = backend.NFS
+	}
+	b, err := p.backendFor(backendName)
 	if err != nil {
-		glog.Errorf("Failed to mount %s:%s %s", server, path, mp)
 		return err
 	}
-	// PV is **not** namespaced
-	tenant, stack, service := volume.Labels["io.wise2c.tenant"], volume.Labels["io.wise2c.stack"], volume.Labels["io.wise2c.service"]
-	pvName := pvName(tenant, stack, service, volume.ObjectMeta.Name)
-	oldPath := filepath.Join(mp, pvName)
-	archivePath := filepath.Join(mp, "archived-"+pvName)
-	glog.Infof("archiving path %s to %s", oldPath, archivePath)
-	return os.Rename(oldPath, archivePath)
+	if err := b.Destroy(volume); err != nil {
+		p.event(volume, v1.EventTypeWarning, "VolumeReclaimFailed", "failed to reclaim %s via backend %s: %v", volume.Name, backendName, err)
+		return err
+	}
+	if onDelete := volume.Annotations[backend.OnDeleteAnnotation]; onDelete != "" {
+		p.event(volume, v1.EventTypeNormal, "VolumeReclaimed", "reclaimed %s via backend %s (onDelete=%s)", volume.Name, backendName, onDelete)
+	} else {
+		p.event(volume, v1.EventTypeNormal, "VolumeReclaimed", "reclaimed %s via backend %s", volume.Name, backendName)
+	}
+	return nil
 }
 
+var metricsAddr = flag.String("metrics-addr", "", "address to serve Prometheus metrics on, e.g. :8080 (disabled if empty)")
+var onDeleteDefault = flag.String("on-delete", backend.OnDeleteArchive, "default reclaim action for StorageClasses that don't set an onDelete parameter: delete|archive|retain (NFS backend only)")
+var nodeAgentAddr = flag.String("node-agent-addr", "nfs-node-agent:7319", "address of the nfs-node-agent headless Service that performs mounts and directory operations on our behalf")
+var enableSnapshots = flag.Bool("enable-snapshots", false, "register the NFSSnapshot CRD and reconcile snapshot/restore requests against it")
+
+const (
+	metricsPollPeriod  = 30 * time.Second
+	snapshotPollPeriod = 10 * time.Second
+)
+
 func main() {
 	flag.Parse()
 	flag.Set("logtostderr", "true")
 
+	serveMetrics(*metricsAddr)
+
 	provisionerName := os.Getenv(provisionerNameKey)
 	if provisionerName == "" {
 		glog.Fatalf("environment variable %s is not set! Please set it.", provisionerNameKey)
 	}
 
+	agent, err := nodeagent.Dial(*nodeAgentAddr)
+	if err != nil {
+		glog.Fatalf("Failed to dial nfs-node-agent at %s: %v", *nodeAgentAddr, err)
+	}
+
 	// Create an InClusterConfig and use it to create a client for the controller
 	// to use to communicate with Kubernetes
 	config, err := rest.InClusterConfig()
@@ -181,7 +199,32 @@ func main() {
 		glog.Fatalf("Error getting server version: %v", err)
 	}
 
-	clientNFSProvisioner := &nfsProvisioner{}
+	metrics := backend.NewMetricsCollector(agent)
+	go metrics.Run(metricsPollPeriod, wait.NeverStop)
+
+	var snapshotClient rest.Interface
+	if *enableSnapshots {
+		apiextensionsClientset, err := apiextensionsclient.NewForConfig(config)
+		if err != nil {
+			glog.Fatalf("Failed to create apiextensions client: %v", err)
+		}
+		if err := snapshot.EnsureCRD(apiextensionsClientset); err != nil {
+			glog.Fatalf("Failed to register the NFSSnapshot CRD: %v", err)
+		}
+		snapshotClient, err = snapshot.NewRESTClient(config)
+		if err != nil {
+			glog.Fatalf("Failed to create NFSSnapshot client: %v", err)
+		}
+		snapshotController := snapshot.NewController(snapshotClient, clientset, agent)
+		go snapshotController.Run(snapshotPollPeriod, wait.NeverStop)
+	}
+
+	backends := map[string]backend.VolumeBackend{
+		backend.NFS:       backend.NewNFSBackend(agent, metrics, *onDeleteDefault, snapshotClient),
+		backend.GlusterFS: backend.NewGlusterFSBackend(clientset),
+	}
+
+	clientNFSProvisioner := &nfsProvisioner{client: clientset, events: newEventRecorder(clientset), backends: backends}
 	// Start the provision controller which will dynamically provision efs NFS
 	// PVs
 	pc := controller.NewProvisionController(clientset, provisionerName, clientNFSProvisioner, serverVersion.GitVersion)
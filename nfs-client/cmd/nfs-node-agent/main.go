@@ -0,0 +1,58 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command nfs-node-agent is the privileged, node-local half of the
+// nfs-client provisioner split: it mounts NFS exports and performs directory
+// operations under them on behalf of the unprivileged controller Deployment,
+// which reaches it over gRPC via a headless Service.
+package main
+
+import (
+	"flag"
+	"net"
+	"time"
+
+	"github.com/golang/glog"
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/nodeagent"
+)
+
+var listenAddr = flag.String("listen", ":7319", "address the node agent's gRPC server listens on")
+var archiveTTL = flag.Duration("archive-ttl", 0, "remove archived PV directories older than this; 0 disables the reaper")
+
+const archiveReaperPeriod = time.Hour
+
+func main() {
+	flag.Parse()
+	flag.Set("logtostderr", "true")
+
+	lis, err := net.Listen("tcp", *listenAddr)
+	if err != nil {
+		glog.Fatalf("failed to listen on %s: %v", *listenAddr, err)
+	}
+
+	go nodeagent.RunArchiveReaper(*archiveTTL, archiveReaperPeriod, wait.NeverStop)
+
+	s := grpc.NewServer()
+	nodeagent.RegisterNodeAgentServer(s, nodeagent.NewServer())
+
+	glog.Infof("nfs-node-agent listening on %s", *listenAddr)
+	if err := s.Serve(lis); err != nil {
+		glog.Fatalf("gRPC server exited: %v", err)
+	}
+}
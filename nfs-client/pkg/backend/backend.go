@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backend generalizes nfs-client-provisioner's original single NFS
+// provisioning path into a pluggable VolumeBackend interface, selected per
+// StorageClass by the "backend" parameter. NFS remains the default so
+// existing StorageClasses that don't set it behave exactly as before.
+package backend
+
+import (
+	"k8s.io/api/core/v1"
+
+	"github.com/kubernetes-incubator/external-storage/lib/controller"
+)
+
+const (
+	// Key is the StorageClass parameter selecting the backend.
+	Key = "backend"
+
+	// NFS is the default backend: the original behavior of mounting an NFS
+	// export and handing out a subdirectory of it per PV.
+	NFS = "nfs"
+	// GlusterFS provisions volumes through a Heketi/gluster-rest endpoint.
+	GlusterFS = "glusterfs"
+
+	// NameAnnotation records which backend provisioned a PV, so Delete can
+	// look up the same one regardless of what the StorageClass says today.
+	NameAnnotation = "nfs-client-provisioner/backend"
+)
+
+// VolumeBackend provisions and releases the storage backing a PV. Ensure is
+// called with the StorageClass parameters already resolved onto options, and
+// the PV name the controller has already computed; it returns the
+// PersistentVolumeSource to put on the PV plus any annotations Destroy will
+// need to find/release what it provisioned.
+type VolumeBackend interface {
+	Ensure(options controller.VolumeOptions, pvName string) (source v1.PersistentVolumeSource, annotations map[string]string, err error)
+	Destroy(pv *v1.PersistentVolume) error
+}
+
+// NameFor resolves the backend StorageClass parameter, defaulting to NFS.
+func NameFor(params map[string]string) string {
+	if name, ok := params[Key]; ok && name != "" {
+		return name
+	}
+	return NFS
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubernetes-incubator/external-storage/lib/controller"
+)
+
+// GlusterFS StorageClass parameters, following the naming the in-tree GlusterFS dynamic
+// provisioner uses so existing StorageClasses written for it need minimal changes.
+const (
+	glusterRestURLKey         = "resturl"
+	glusterRestUserKey        = "restuser"
+	glusterSecretNameKey      = "secretname"
+	glusterSecretNamespaceKey = "secretnamespace"
+
+	glusterSecretDataKey = "key"
+
+	glusterVolumeIDAnnotation  = "nfs-client-provisioner/glusterfs-volume-id"
+	glusterRestURLAnnotation   = "nfs-client-provisioner/glusterfs-resturl"
+	glusterRestUserAnnotation  = "nfs-client-provisioner/glusterfs-restuser"
+	glusterSecretRefAnnotation = "nfs-client-provisioner/glusterfs-secretref" // "namespace/name"
+	glusterEndpointsAnnotation = "nfs-client-provisioner/glusterfs-endpoints"
+	glusterEndpointsNamePrefix = "glusterfs-dynamic-"
+
+	bytesPerGiB int64 = 1024 * 1024 * 1024
+)
+
+// GlusterFSBackend provisions volumes through a Heketi/gluster-rest endpoint, following
+// the pattern the in-tree GlusterFS dynamic provisioner uses: create a sized volume over
+// REST, mirror the brick endpoints it reports into a PVC-namespaced Endpoints object, and
+// point the PV's Glusterfs source at that Endpoints object.
+type GlusterFSBackend struct {
+	Client     kubernetes.Interface
+	HTTPClient *http.Client
+}
+
+// NewGlusterFSBackend returns a GlusterFSBackend that creates its Endpoints/Service
+// objects through client.
+func NewGlusterFSBackend(client kubernetes.Interface) *GlusterFSBackend {
+	return &GlusterFSBackend{Client: client, HTTPClient: http.DefaultClient}
+}
+
+var _ VolumeBackend = &GlusterFSBackend{}
+
+type glusterVolumeCreateRequest struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"` // GiB
+}
+
+type glusterVolumeCreateResponse struct {
+	ID     string `json:"id"`
+	Bricks []struct {
+		Host string `json:"host"`
+	} `json:"bricks"`
+}
+
+func (b *GlusterFSBackend) restPassword(params map[string]string) (string, error) {
+	secretName := params[glusterSecretNameKey]
+	secretNamespace := params[glusterSecretNamespaceKey]
+	if secretName == "" {
+		return "", nil
+	}
+	if secretNamespace == "" {
+		secretNamespace = "default"
+	}
+	secret, err := b.Client.CoreV1().Secrets(secretNamespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("unable to get secret %s/%s: %v", secretNamespace, secretName, err)
+	}
+	return string(secret.Data[glusterSecretDataKey]), nil
+}
+
+func (b *GlusterFSBackend) createVolume(params map[string]string, password string, name string, sizeGiB int64) (*glusterVolumeCreateResponse, error) {
+	reqBody, err := json.Marshal(glusterVolumeCreateRequest{Name: name, Size: sizeGiB})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", params[glusterRestURLKey]+"/volumes", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if params[glusterRestUserKey] != "" {
+		req.SetBasicAuth(params[glusterRestUserKey], password)
+	}
+	resp, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gluster-rest volume create request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("gluster-rest volume create returned %s: %s", resp.Status, body)
+	}
+	var out glusterVolumeCreateResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return nil, fmt.Errorf("unable to parse gluster-rest volume create response: %v", err)
+	}
+	return &out, nil
+}
+
+func (b *GlusterFSBackend) deleteVolume(params map[string]string, password string, id string) error {
+	req, err := http.NewRequest("DELETE", params[glusterRestURLKey]+"/volumes/"+id, nil)
+	if err != nil {
+		return err
+	}
+	if params[glusterRestUserKey] != "" {
+		req.SetBasicAuth(params[glusterRestUserKey], password)
+	}
+	resp, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gluster-rest volume delete request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("gluster-rest volume delete returned %s: %s", resp.Status, body)
+	}
+	return nil
+}
+
+// ensureEndpoints creates (or replaces) the Endpoints object that points GlusterFS
+// client mounts at hosts, matching the in-tree provisioner's dedicated-per-PV Endpoints
+// so cleanup on Destroy never has to worry about other PVs referencing it.
+func (b *GlusterFSBackend) ensureEndpoints(namespace, name string, hosts []string) error {
+	addrs := make([]v1.EndpointAddress, 0, len(hosts))
+	for _, h := range hosts {
+		addrs = append(addrs, v1.EndpointAddress{IP: h})
+	}
+	endpoints := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Subsets: []v1.EndpointSubset{{
+			Addresses: addrs,
+			Ports:     []v1.EndpointPort{{Port: 1, Protocol: v1.ProtocolTCP}},
+		}},
+	}
+	if _, err := b.Client.CoreV1().Endpoints(namespace).Create(endpoints); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+		if _, err := b.Client.CoreV1().Endpoints(namespace).Update(endpoints); err != nil {
+			return err
+		}
+	}
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{{Port: 1, Protocol: v1.ProtocolTCP, TargetPort: intstr.FromInt(1)}},
+		},
+	}
+	if _, err := b.Client.CoreV1().Services(namespace).Create(service); err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// Ensure creates a Heketi/gluster-rest volume sized to the PVC's request and mirrors its
+// brick endpoints into a dedicated Endpoints+Service pair in the PVC's namespace.
+func (b *GlusterFSBackend) Ensure(options controller.VolumeOptions, pvName string) (v1.PersistentVolumeSource, map[string]string, error) {
+	params := options.Parameters
+	if params[glusterRestURLKey] == "" {
+		return v1.PersistentVolumeSource{}, nil, fmt.Errorf("%s parameter required", glusterRestURLKey)
+	}
+	password, err := b.restPassword(params)
+	if err != nil {
+		return v1.PersistentVolumeSource{}, nil, err
+	}
+
+	requestBytes := options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)]
+	sizeGiB := (requestBytes.Value() + bytesPerGiB - 1) / bytesPerGiB
+	if sizeGiB < 1 {
+		sizeGiB = 1
+	}
+
+	vol, err := b.createVolume(params, password, pvName, sizeGiB)
+	if err != nil {
+		return v1.PersistentVolumeSource{}, nil, err
+	}
+	hosts := make([]string, 0, len(vol.Bricks))
+	for _, brick := range vol.Bricks {
+		hosts = append(hosts, brick.Host)
+	}
+	if len(hosts) == 0 {
+		return v1.PersistentVolumeSource{}, nil, fmt.Errorf("gluster-rest volume %s reported no brick endpoints", vol.ID)
+	}
+
+	epName := glusterEndpointsNamePrefix + pvName
+	if err := b.ensureEndpoints(options.PVC.Namespace, epName, hosts); err != nil {
+		glog.Errorf("failed to create endpoints %s/%s for gluster volume %s: %v", options.PVC.Namespace, epName, vol.ID, err)
+		if derr := b.deleteVolume(params, password, vol.ID); derr != nil {
+			glog.Warningf("failed to clean up gluster volume %s after endpoints error: %v", vol.ID, derr)
+		}
+		return v1.PersistentVolumeSource{}, nil, err
+	}
+
+	source := v1.PersistentVolumeSource{
+		Glusterfs: &v1.GlusterfsVolumeSource{
+			EndpointsName: epName,
+			Path:          pvName,
+			ReadOnly:      false,
+		},
+	}
+	annotations := map[string]string{
+		glusterVolumeIDAnnotation:  vol.ID,
+		glusterRestURLAnnotation:   params[glusterRestURLKey],
+		glusterRestUserAnnotation:  params[glusterRestUserKey],
+		glusterEndpointsAnnotation: epName,
+	}
+	if params[glusterSecretNameKey] != "" {
+		secretNamespace := params[glusterSecretNamespaceKey]
+		if secretNamespace == "" {
+			secretNamespace = "default"
+		}
+		annotations[glusterSecretRefAnnotation] = secretNamespace + "/" + params[glusterSecretNameKey]
+	}
+	return source, annotations, nil
+}
+
+// Destroy deletes the gluster-rest volume pv was backed by and the Endpoints/Service
+// pair Ensure created for it. Since each PV gets its own dedicated Endpoints object,
+// cleanup never has to reference-count other PVs against it.
+func (b *GlusterFSBackend) Destroy(pv *v1.PersistentVolume) error {
+	id := pv.Annotations[glusterVolumeIDAnnotation]
+	if id == "" {
+		return fmt.Errorf("PV %s is missing the %s annotation", pv.Name, glusterVolumeIDAnnotation)
+	}
+	params := map[string]string{
+		glusterRestURLKey:  pv.Annotations[glusterRestURLAnnotation],
+		glusterRestUserKey: pv.Annotations[glusterRestUserAnnotation],
+	}
+	var password string
+	if ref := pv.Annotations[glusterSecretRefAnnotation]; ref != "" {
+		namespace, name := splitNamespacedName(ref)
+		params[glusterSecretNameKey] = name
+		params[glusterSecretNamespaceKey] = namespace
+		pw, err := b.restPassword(params)
+		if err != nil {
+			return err
+		}
+		password = pw
+	}
+
+	if err := b.deleteVolume(params, password, id); err != nil {
+		return err
+	}
+
+	epName := pv.Annotations[glusterEndpointsAnnotation]
+	namespace := pv.Spec.ClaimRef.Namespace
+	if epName != "" && namespace != "" {
+		if err := b.Client.CoreV1().Services(namespace).Delete(epName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			glog.Warningf("failed to delete service %s/%s: %v", namespace, epName, err)
+		}
+		if err := b.Client.CoreV1().Endpoints(namespace).Delete(epName, &metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			glog.Warningf("failed to delete endpoints %s/%s: %v", namespace, epName, err)
+		}
+	}
+	return nil
+}
+
+func splitNamespacedName(ref string) (namespace string, name string) {
+	for i := 0; i < len(ref); i++ {
+		if ref[i] == '/' {
+			return ref[:i], ref[i+1:]
+		}
+	}
+	return "", ref
+}
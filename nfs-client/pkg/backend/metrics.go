@@ -0,0 +1,145 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/nodeagent"
+)
+
+var (
+	pvCapacityBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nfs_provisioner_pv_capacity_bytes",
+		Help: "Total capacity in bytes of the filesystem backing a provisioned PV.",
+	}, []string{"tenant", "stack", "service", "pvName"})
+
+	pvUsedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nfs_provisioner_pv_used_bytes",
+		Help: "Bytes used under a provisioned PV's directory.",
+	}, []string{"tenant", "stack", "service", "pvName"})
+
+	pvAvailableBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nfs_provisioner_pv_available_bytes",
+		Help: "Bytes available on the filesystem backing a provisioned PV.",
+	}, []string{"tenant", "stack", "service", "pvName"})
+
+	pvInodesFree = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nfs_provisioner_pv_inodes_free",
+		Help: "Free inodes on the filesystem backing a provisioned PV.",
+	}, []string{"tenant", "stack", "service", "pvName"})
+)
+
+func init() {
+	prometheus.MustRegister(pvCapacityBytes, pvUsedBytes, pvAvailableBytes, pvInodesFree)
+}
+
+// ProvisionedVolume is the bookkeeping MetricsCollector needs to statfs a PV's
+// directory and label the resulting samples the same way the PV itself is labelled. It
+// only applies to the NFS backend, since GlusterFS volumes aren't a directory under an
+// export the node agent has mounted, which is where the statfs happens.
+type ProvisionedVolume struct {
+	Tenant, Stack, Service, PVName string
+	Path                           string
+}
+
+// MetricsCollector periodically asks the node agent to statfs(2) and du every
+// provisioned directory it's told about, and publishes capacity/available/inode gauges
+// for it, plus used bytes. All PVs under an export share its free space, so
+// capacity/available/inodes are identical across a server's PVs; used bytes is the only
+// one that varies per PV.
+type MetricsCollector struct {
+	agent *nodeagent.Client
+
+	mu      sync.Mutex
+	volumes map[string]ProvisionedVolume
+}
+
+// NewMetricsCollector returns a MetricsCollector that collects through agent, with
+// nothing tracked yet.
+func NewMetricsCollector(agent *nodeagent.Client) *MetricsCollector {
+	return &MetricsCollector{agent: agent, volumes: make(map[string]ProvisionedVolume)}
+}
+
+// Track starts reporting gauges for pv on the next collection tick.
+func (c *MetricsCollector) Track(pv ProvisionedVolume) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.volumes[pv.PVName] = pv
+}
+
+// Untrack stops reporting gauges for pvName and deletes their last-seen values.
+func (c *MetricsCollector) Untrack(pvName string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	pv, ok := c.volumes[pvName]
+	delete(c.volumes, pvName)
+	c.mu.Unlock()
+	if !ok {
+		return
+	}
+	labels := prometheus.Labels{"tenant": pv.Tenant, "stack": pv.Stack, "service": pv.Service, "pvName": pv.PVName}
+	pvCapacityBytes.Delete(labels)
+	pvUsedBytes.Delete(labels)
+	pvAvailableBytes.Delete(labels)
+	pvInodesFree.Delete(labels)
+}
+
+// Run polls every tracked volume on period until stopCh is closed.
+func (c *MetricsCollector) Run(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.collect()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (c *MetricsCollector) collect() {
+	c.mu.Lock()
+	volumes := make([]ProvisionedVolume, 0, len(c.volumes))
+	for _, v := range c.volumes {
+		volumes = append(volumes, v)
+	}
+	c.mu.Unlock()
+
+	for _, v := range volumes {
+		usage, err := c.agent.Usage(v.Path)
+		if err != nil {
+			glog.Warningf("unable to collect usage for %s for metrics: %v", v.Path, err)
+			continue
+		}
+		labels := prometheus.Labels{"tenant": v.Tenant, "stack": v.Stack, "service": v.Service, "pvName": v.PVName}
+		pvCapacityBytes.With(labels).Set(float64(usage.CapacityBytes))
+		pvAvailableBytes.With(labels).Set(float64(usage.AvailableBytes))
+		pvInodesFree.With(labels).Set(float64(usage.InodesFree))
+		pvUsedBytes.With(labels).Set(float64(usage.UsedBytes))
+	}
+}
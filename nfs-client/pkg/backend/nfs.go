@@ -0,0 +1,205 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"github.com/golang/glog"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubernetes-incubator/external-storage/lib/controller"
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/nodeagent"
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/snapshot"
+)
+
+// RestoreFromKey is the StorageClass parameter naming an NFSSnapshot (in the PVC's
+// namespace) whose cloned directory should seed the new PV instead of an empty directory.
+const RestoreFromKey = "restoreFrom"
+
+// DirName builds the per-PV directory/export path segment this provisioner has always
+// used, namespaced by tenant/stack/service to keep PVs from different callers apart on
+// a shared export.
+func DirName(tenant, stack, service, name string) string {
+	return fmt.Sprintf("%s-%s-%s-%s", tenant, stack, service, name)
+}
+
+// NFSBackend is the original nfs-client-provisioner behavior: mount an NFS export via
+// the node agent and hand out a subdirectory of it per PV, optionally enforcing an
+// XFS/ext4 project quota and choosing how Destroy reclaims the directory.
+type NFSBackend struct {
+	Agent           *nodeagent.Client
+	Metrics         *MetricsCollector
+	DefaultOnDelete string
+	// Snapshots is the NFSSnapshot REST client used to resolve the restoreFrom
+	// StorageClass parameter. A nil Snapshots makes restoreFrom an error, which is fine
+	// for deployments that never installed the NFSSnapshot CRD.
+	Snapshots rest.Interface
+}
+
+// NewNFSBackend returns an NFSBackend that mounts exports through agent. snapshots may be
+// nil if the NFSSnapshot CRD isn't in use, in which case restoreFrom StorageClasses fail.
+func NewNFSBackend(agent *nodeagent.Client, metrics *MetricsCollector, defaultOnDelete string, snapshots rest.Interface) *NFSBackend {
+	return &NFSBackend{Agent: agent, Metrics: metrics, DefaultOnDelete: defaultOnDelete, Snapshots: snapshots}
+}
+
+var _ VolumeBackend = &NFSBackend{}
+
+// enforceQuota applies the StorageClass's quotaMode to the freshly created fullPath and
+// returns any PV annotations that need to persist across to Destroy (namely the project
+// id, so the quota can be released again). quotaMode defaults to "project": best-effort
+// enforcement that falls back to an unenforced volume (with a warning) when the export's
+// filesystem doesn't support project quotas. "fail" makes that fallback a hard error, and
+// "none" skips quota handling entirely.
+func (b *NFSBackend) enforceQuota(mp string, fullPath string, pvName string, params map[string]string, options controller.VolumeOptions) (map[string]string, error) {
+	quotaMode := params[QuotaModeKey]
+	if quotaMode == "" {
+		quotaMode = QuotaModeProject
+	}
+	if quotaMode == QuotaModeNone {
+		return nil, nil
+	}
+
+	supported, err := b.Agent.QuotaSupported(mp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to check quota support for %s: %v", mp, err)
+	}
+	if !supported {
+		if quotaMode == QuotaModeFail {
+			return nil, fmt.Errorf("quotaMode %q requires project quota support, but %s does not provide it", quotaMode, mp)
+		}
+		glog.Warningf("quotaMode %q requested for %s but %s does not support project quotas; provisioning without a size limit", quotaMode, pvName, mp)
+		return nil, nil
+	}
+
+	hardBytes := options.PVC.Spec.Resources.Requests[v1.ResourceName(v1.ResourceStorage)]
+	id, err := b.Agent.SetQuota(mp, fullPath, hardBytes.Value())
+	if err != nil {
+		if quotaMode == QuotaModeFail {
+			return nil, err
+		}
+		glog.Warningf("unable to enforce quota for %s: %v", pvName, err)
+		return nil, nil
+	}
+	return map[string]string{QuotaProjectIDAnnotation: strconv.FormatUint(uint64(id), 10)}, nil
+}
+
+// restoreSnapshot looks up the named NFSSnapshot in options.PVC's namespace and clones
+// its Status.Path into fullPath, so the new PV starts out as a copy of the snapshot
+// instead of an empty directory.
+func (b *NFSBackend) restoreSnapshot(options controller.VolumeOptions, name string, fullPath string) error {
+	if b.Snapshots == nil {
+		return fmt.Errorf("restoreFrom=%s requires the NFSSnapshot CRD, which isn't configured", name)
+	}
+	snap := &snapshot.NFSSnapshot{}
+	if err := b.Snapshots.Get().Namespace(options.PVC.Namespace).Resource("nfssnapshots").Name(name).Do().Into(snap); err != nil {
+		return fmt.Errorf("unable to get NFSSnapshot %s/%s: %v", options.PVC.Namespace, name, err)
+	}
+	if snap.Status.Phase != snapshot.NFSSnapshotReady {
+		return fmt.Errorf("NFSSnapshot %s/%s is not ready (phase %q)", options.PVC.Namespace, name, snap.Status.Phase)
+	}
+	if _, err := b.Agent.Clone(snap.Status.Path, fullPath, ""); err != nil {
+		return fmt.Errorf("unable to restore from NFSSnapshot %s/%s: %v", options.PVC.Namespace, name, err)
+	}
+	return nil
+}
+
+// Ensure mounts the nfsServer:nfsPath StorageClass parameters via the node agent,
+// creates pvName's directory under it, and applies any requested quota.
+func (b *NFSBackend) Ensure(options controller.VolumeOptions, pvName string) (v1.PersistentVolumeSource, map[string]string, error) {
+	params := options.Parameters
+	if params["nfsPath"] == "" || params["nfsServer"] == "" {
+		return v1.PersistentVolumeSource{}, nil, fmt.Errorf("nfsPath and nfsServer parameters required")
+	}
+	server := params["nfsServer"]
+	nfsPath := params["nfsPath"]
+
+	mp, err := b.Agent.EnsureMount(server, nfsPath)
+	if err != nil {
+		return v1.PersistentVolumeSource{}, nil, fmt.Errorf("unable to mount NFS volume: %v", err)
+	}
+	fullPath := filepath.Join(mp, pvName)
+	if restoreFrom := params[RestoreFromKey]; restoreFrom != "" {
+		if err := b.restoreSnapshot(options, restoreFrom, fullPath); err != nil {
+			return v1.PersistentVolumeSource{}, nil, err
+		}
+	} else if err := b.Agent.Mkdir(fullPath, 0777); err != nil {
+		return v1.PersistentVolumeSource{}, nil, fmt.Errorf("unable to create directory to provision new pv: %v", err)
+	}
+
+	quotaAnnotations, err := b.enforceQuota(mp, fullPath, pvName, params, options)
+	if err != nil {
+		b.Agent.RemoveAll(fullPath)
+		return v1.PersistentVolumeSource{}, nil, err
+	}
+	annotations := map[string]string{OnDeleteAnnotation: onDeleteFor(params, b.DefaultOnDelete)}
+	for k, v := range quotaAnnotations {
+		annotations[k] = v
+	}
+
+	b.Metrics.Track(ProvisionedVolume{
+		Tenant:  options.Tenant,
+		Stack:   options.Stack,
+		Service: options.Service,
+		PVName:  options.PVName,
+		Path:    fullPath,
+	})
+
+	source := v1.PersistentVolumeSource{
+		NFS: &v1.NFSVolumeSource{
+			Server:   server,
+			Path:     filepath.Join(nfsPath, pvName),
+			ReadOnly: false,
+		},
+	}
+	return source, annotations, nil
+}
+
+// Destroy releases the quota (if any) and reclaims pv's directory per its onDelete
+// annotation.
+func (b *NFSBackend) Destroy(pv *v1.PersistentVolume) error {
+	server := pv.Spec.PersistentVolumeSource.NFS.Server
+	// Path includes the dynamic volume name
+	nfsPath := path.Dir(pv.Spec.PersistentVolumeSource.NFS.Path)
+	mp, err := b.Agent.EnsureMount(server, nfsPath)
+	if err != nil {
+		return fmt.Errorf("unable to mount %s:%s: %v", server, nfsPath, err)
+	}
+	// PV is **not** namespaced
+	tenant, stack, service := pv.Labels["io.wise2c.tenant"], pv.Labels["io.wise2c.stack"], pv.Labels["io.wise2c.service"]
+	pvName := DirName(tenant, stack, service, pv.ObjectMeta.Name)
+
+	if idStr, ok := pv.Annotations[QuotaProjectIDAnnotation]; ok {
+		if id, err := strconv.ParseUint(idStr, 10, 32); err != nil {
+			glog.Warningf("invalid %s annotation %q on %s: %v", QuotaProjectIDAnnotation, idStr, pvName, err)
+		} else if err := b.Agent.ClearQuota(mp, uint32(id)); err != nil {
+			glog.Warningf("failed to release quota project %d for %s: %v", id, pvName, err)
+		}
+	}
+
+	b.Metrics.Untrack(pv.ObjectMeta.Name)
+
+	onDelete := pv.Annotations[OnDeleteAnnotation]
+	if onDelete == "" {
+		onDelete = b.DefaultOnDelete
+	}
+	return reclaimVolume(b.Agent, mp, pvName, onDelete)
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+const (
+	// QuotaModeKey is the StorageClass parameter that selects how capacity is enforced.
+	// Only meaningful for the NFS backend.
+	QuotaModeKey = "quotaMode"
+
+	QuotaModeProject = "project"
+	QuotaModeNone    = "none"
+	QuotaModeFail    = "fail"
+
+	// QuotaProjectIDAnnotation records the XFS/ext4 project id allocated to a PV so
+	// Destroy can release the quota again. The project id itself is only meaningful to
+	// the node agent that set it, which is also the only place that shells out to
+	// xfs_quota; see pkg/nodeagent's QuotaSupported/SetQuota/ClearQuota RPCs.
+	QuotaProjectIDAnnotation = "nfs-client-provisioner/projectId"
+)
@@ -0,0 +1,67 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"path/filepath"
+
+	"github.com/golang/glog"
+
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/nodeagent"
+)
+
+const (
+	// OnDeleteKey is the StorageClass parameter selecting the reclaim action for the
+	// NFS backend. Falls back to NFSBackend.DefaultOnDelete when unset, keeping
+	// existing StorageClasses behaving exactly as before this was made configurable.
+	OnDeleteKey = "onDelete"
+
+	OnDeleteDelete  = "delete"
+	OnDeleteArchive = "archive"
+	OnDeleteRetain  = "retain"
+
+	// OnDeleteAnnotation records the onDelete action a PV was provisioned with, so
+	// Destroy applies the action the StorageClass asked for at provisioning time even
+	// if the StorageClass has since changed or been removed.
+	OnDeleteAnnotation = "nfs-client-provisioner/onDelete"
+)
+
+func onDeleteFor(params map[string]string, defaultOnDelete string) string {
+	if v, ok := params[OnDeleteKey]; ok && v != "" {
+		return v
+	}
+	return defaultOnDelete
+}
+
+// reclaimVolume disposes of the provisioned directory pvName under mp according to
+// onDelete, which must be one of OnDeleteDelete/OnDeleteArchive/OnDeleteRetain. The
+// actual directory move happens on the node agent, which is the only thing with mp
+// mounted; archiving's TTL sidecar bookkeeping and expiry sweep live there too.
+func reclaimVolume(agent *nodeagent.Client, mp string, pvName string, onDelete string) error {
+	oldPath := filepath.Join(mp, pvName)
+	switch onDelete {
+	case OnDeleteDelete:
+		return agent.RemoveAll(oldPath)
+	case OnDeleteRetain:
+		glog.Infof("retaining path %s per onDelete=retain", oldPath)
+		return nil
+	default:
+		archivePath := filepath.Join(mp, nodeagent.ArchivePrefix+pvName)
+		glog.Infof("archiving path %s to %s", oldPath, archivePath)
+		return agent.Rename(oldPath, archivePath)
+	}
+}
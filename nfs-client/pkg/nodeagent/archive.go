@@ -0,0 +1,133 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	linuxproc "github.com/c9s/goprocinfo/linux"
+	"github.com/golang/glog"
+)
+
+const (
+	// ArchivePrefix is the directory name prefix the controller's onDelete=archive
+	// reclaim action renames a PV directory to.
+	ArchivePrefix = "archived-"
+
+	// archiveMetaFile is a sidecar dropped into an archived directory recording when
+	// it was archived, so TTL expiry survives agent restarts.
+	archiveMetaFile = ".archived.json"
+)
+
+// archiveMeta is the sidecar JSON written inside an archived PV directory.
+type archiveMeta struct {
+	ArchivedAt time.Time `json:"archivedAt"`
+}
+
+// writeArchiveMeta records the current time as dir's archival time. Failures are logged
+// and otherwise swallowed: worst case the reaper has no sidecar to read and, since it
+// can't tell archival time from content mtime any other reliable way, leaves dir alone
+// until an operator notices and removes it by hand.
+func writeArchiveMeta(dir string) {
+	b, err := json.Marshal(archiveMeta{ArchivedAt: time.Now()})
+	if err != nil {
+		glog.Warningf("unable to record archive metadata for %s: %v", dir, err)
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, archiveMetaFile), b, 0644); err != nil {
+		glog.Warningf("unable to write archive metadata for %s: %v", dir, err)
+	}
+}
+
+// mountedExports lists the mount points this agent currently has mounted under
+// mountPath, i.e. the set of NFS exports it might have archived directories under.
+func mountedExports() []string {
+	mps, err := linuxproc.ReadMounts("/proc/mounts")
+	if err != nil {
+		glog.Warningf("unable to read /proc/mounts: %v", err)
+		return nil
+	}
+	var exports []string
+	for _, m := range mps.Mounts {
+		if strings.HasPrefix(m.MountPoint, mountPath+"/") {
+			exports = append(exports, m.MountPoint)
+		}
+	}
+	return exports
+}
+
+// sweepExpiredArchives scans every mounted export for ArchivePrefix directories and
+// os.RemoveAll's the ones whose archiveMeta sidecar says they're older than ttl. A
+// directory's own mtime reflects when its *contents* were last written, not when it was
+// renamed into the archive (rename(2) only bumps ctime, which isn't exposed by os.Stat),
+// so a directory missing its sidecar has no reliable archival time and is left alone
+// rather than reaped on a guess.
+func sweepExpiredArchives(ttl time.Duration) {
+	for _, export := range mountedExports() {
+		entries, err := ioutil.ReadDir(export)
+		if err != nil {
+			glog.Warningf("unable to scan %s for expired archives: %v", export, err)
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() || !strings.HasPrefix(entry.Name(), ArchivePrefix) {
+				continue
+			}
+			dir := filepath.Join(export, entry.Name())
+			b, err := ioutil.ReadFile(filepath.Join(dir, archiveMetaFile))
+			if err != nil {
+				glog.Warningf("archive %s has no archive metadata, skipping TTL check", dir)
+				continue
+			}
+			var meta archiveMeta
+			if err := json.Unmarshal(b, &meta); err != nil {
+				glog.Warningf("archive %s has unreadable archive metadata, skipping TTL check: %v", dir, err)
+				continue
+			}
+			if time.Since(meta.ArchivedAt) < ttl {
+				continue
+			}
+			glog.Infof("archive %s exceeded TTL %s, removing", dir, ttl)
+			if err := os.RemoveAll(dir); err != nil {
+				glog.Warningf("failed to remove expired archive %s: %v", dir, err)
+			}
+		}
+	}
+}
+
+// RunArchiveReaper sweeps for TTL-expired archives on period until stopCh closes. A
+// non-positive ttl disables the reaper entirely.
+func RunArchiveReaper(ttl time.Duration, period time.Duration, stopCh <-chan struct{}) {
+	if ttl <= 0 {
+		return
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			sweepExpiredArchives(ttl)
+		case <-stopCh:
+			return
+		}
+	}
+}
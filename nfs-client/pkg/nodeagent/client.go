@@ -0,0 +1,127 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"os"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+)
+
+// Client is a thin, blocking wrapper around the generated NodeAgentClient
+// that the controller uses so call sites don't have to juggle contexts and
+// request/reply structs for what are, from the controller's point of view,
+// plain synchronous filesystem calls.
+type Client struct {
+	conn *grpc.ClientConn
+	rpc  NodeAgentClient
+}
+
+// Dial connects to the node agent at addr, e.g. the headless Service DNS
+// name "nfs-node-agent:7319". The connection is shared across calls and
+// reconnects transparently, matching how the controller dialed no particular
+// node before the split: any agent can mount any export.
+func Dial(addr string) (*Client, error) {
+	conn, err := grpc.Dial(addr, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, rpc: NewNodeAgentClient(conn)}, nil
+}
+
+// Close releases the underlying gRPC connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// EnsureMount asks the agent to mount server:path and returns the agent's
+// local mount point for it.
+func (c *Client) EnsureMount(server, path string) (string, error) {
+	reply, err := c.rpc.EnsureMount(context.Background(), &EnsureMountRequest{Server: server, Path: path})
+	if err != nil {
+		return "", err
+	}
+	return reply.MountPoint, nil
+}
+
+// Mkdir asks the agent to create path (and any missing parents) with mode.
+func (c *Client) Mkdir(path string, mode os.FileMode) error {
+	_, err := c.rpc.Mkdir(context.Background(), &MkdirRequest{Path: path, Mode: uint32(mode)})
+	return err
+}
+
+// Rename asks the agent to rename oldPath to newPath.
+func (c *Client) Rename(oldPath, newPath string) error {
+	_, err := c.rpc.Rename(context.Background(), &RenameRequest{OldPath: oldPath, NewPath: newPath})
+	return err
+}
+
+// Stat asks the agent to stat path.
+func (c *Client) Stat(path string) (*StatReply, error) {
+	return c.rpc.Stat(context.Background(), &StatRequest{Path: path})
+}
+
+// RemoveAll asks the agent to recursively remove path.
+func (c *Client) RemoveAll(path string) error {
+	_, err := c.rpc.RemoveAll(context.Background(), &RemoveAllRequest{Path: path})
+	return err
+}
+
+// Clone asks the agent to make dst a point-in-time copy of src. technique forces a
+// specific clone backend (one of the CloneBackend constants); empty auto-detects one from
+// src's filesystem. It returns the backend the agent actually used.
+func (c *Client) Clone(src, dst, technique string) (string, error) {
+	reply, err := c.rpc.Clone(context.Background(), &CloneRequest{Src: src, Dst: dst, Technique: technique})
+	if err != nil {
+		return "", err
+	}
+	return reply.Backend, nil
+}
+
+// QuotaSupported asks the agent whether mp's filesystem can enforce a per-directory
+// project quota.
+func (c *Client) QuotaSupported(mp string) (bool, error) {
+	reply, err := c.rpc.QuotaSupported(context.Background(), &QuotaSupportedRequest{MountPoint: mp})
+	if err != nil {
+		return false, err
+	}
+	return reply.Supported, nil
+}
+
+// SetQuota asks the agent to allocate a project id on mp's filesystem, assign it to dir,
+// and cap it at hardBytes. It returns the allocated project id.
+func (c *Client) SetQuota(mp, dir string, hardBytes int64) (uint32, error) {
+	reply, err := c.rpc.SetQuota(context.Background(), &SetQuotaRequest{MountPoint: mp, Dir: dir, HardBytes: hardBytes})
+	if err != nil {
+		return 0, err
+	}
+	return reply.ProjectId, nil
+}
+
+// ClearQuota asks the agent to drop the hard limit previously set for projectID on mp.
+// The agent does not hand projectID out again.
+func (c *Client) ClearQuota(mp string, projectID uint32) error {
+	_, err := c.rpc.ClearQuota(context.Background(), &ClearQuotaRequest{MountPoint: mp, ProjectId: projectID})
+	return err
+}
+
+// Usage asks the agent to statfs and du path, since only the agent has the export
+// mounted to do either.
+func (c *Client) Usage(path string) (*UsageReply, error) {
+	return c.rpc.Usage(context.Background(), &UsageRequest{Path: path})
+}
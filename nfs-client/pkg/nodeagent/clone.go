@@ -0,0 +1,135 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	linuxproc "github.com/c9s/goprocinfo/linux"
+	"github.com/golang/glog"
+)
+
+// snapshotMetaFile is a sidecar dropped into a cloned directory recording where it
+// came from, so pkg/snapshot's Delete handling doesn't need anything beyond the path it
+// already tracks to describe a snapshot's provenance.
+const snapshotMetaFile = ".snapshot.json"
+
+// snapshotMeta is the sidecar JSON written inside a cloned directory.
+type snapshotMeta struct {
+	Source   string    `json:"source"`
+	Backend  string    `json:"backend"`
+	ClonedAt time.Time `json:"clonedAt"`
+}
+
+// writeSnapshotMeta records where dst was cloned from and with what technique. Failures
+// are logged and swallowed, matching writeArchiveMeta: the clone itself already succeeded,
+// and losing this sidecar only costs provenance information, not correctness.
+func writeSnapshotMeta(dst, src, backend string) {
+	b, err := json.Marshal(snapshotMeta{Source: src, Backend: backend, ClonedAt: time.Now()})
+	if err != nil {
+		glog.Warningf("unable to record snapshot metadata for %s: %v", dst, err)
+		return
+	}
+	if err := ioutil.WriteFile(filepath.Join(dst, snapshotMetaFile), b, 0644); err != nil {
+		glog.Warningf("unable to write snapshot metadata for %s: %v", dst, err)
+	}
+}
+
+const (
+	// CloneBackendBtrfs and friends name the clone techniques Clone can use, both as
+	// the technique a caller may force and as the backend CloneReply reports back.
+	CloneBackendBtrfs   = "btrfs"
+	CloneBackendZFS     = "zfs"
+	CloneBackendReflink = "reflink"
+	CloneBackendRsync   = "rsync"
+)
+
+// fsTypeOf returns the filesystem type /proc/mounts reports for the mount point src is
+// under, walking up src's path until a mount point matches. Returns "" if none do.
+func fsTypeOf(path string) string {
+	mps, err := linuxproc.ReadMounts("/proc/mounts")
+	if err != nil {
+		return ""
+	}
+	var best linuxproc.Mount
+	for _, m := range mps.Mounts {
+		if strings.HasPrefix(path, m.MountPoint) && len(m.MountPoint) > len(best.MountPoint) {
+			best = m
+		}
+	}
+	return best.FSType
+}
+
+// cloneTechniqueFor picks the clone technique Clone should use for src when the caller
+// didn't force one: btrfs/zfs subvolume snapshots where the underlying filesystem
+// supports them, falling back to a reflink-aware cp and finally rsync for anything else.
+func cloneTechniqueFor(src string) string {
+	switch fsTypeOf(src) {
+	case "btrfs":
+		return CloneBackendBtrfs
+	case "zfs":
+		return CloneBackendZFS
+	default:
+		return CloneBackendReflink
+	}
+}
+
+// clone makes dst a point-in-time copy of src using technique, falling back to rsync if
+// technique is reflink and cp fails outright. Where reflinks aren't supported,
+// --reflink=auto already makes cp do a full copy, so that case doesn't reach rsync.
+func clone(src, dst, technique string) error {
+	switch technique {
+	case CloneBackendBtrfs:
+		if out, err := exec.Command("btrfs", "subvolume", "snapshot", src, dst).CombinedOutput(); err != nil {
+			return fmt.Errorf("btrfs subvolume snapshot %s %s: %v: %s", src, dst, err, out)
+		}
+		return nil
+	case CloneBackendZFS:
+		if out, err := exec.Command("zfs", "snapshot", dst).CombinedOutput(); err != nil {
+			return fmt.Errorf("zfs snapshot %s: %v: %s", dst, err, out)
+		}
+		return nil
+	case CloneBackendReflink:
+		out, err := exec.Command("cp", "-a", "--reflink=auto", src, dst).CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		// cp failed outright (not just a silent fallback to a full copy, which
+		// --reflink=auto already handles on its own); fall through to rsync.
+		return rsyncClone(src, dst, fmt.Errorf("cp -a --reflink=auto %s %s: %v: %s", src, dst, err, out))
+	case CloneBackendRsync:
+		return rsyncClone(src, dst, nil)
+	default:
+		return fmt.Errorf("unknown clone technique %q", technique)
+	}
+}
+
+func rsyncClone(src, dst string, cpErr error) error {
+	if out, err := exec.Command("rsync", "-a", src+"/", dst+"/").CombinedOutput(); err != nil {
+		if cpErr != nil {
+			return fmt.Errorf("%v; rsync -a %s/ %s/ also failed: %v: %s", cpErr, src, dst, err, out)
+		}
+		return fmt.Errorf("rsync -a %s/ %s/: %v: %s", src, dst, err, out)
+	}
+	return nil
+}
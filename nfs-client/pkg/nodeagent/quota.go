@@ -0,0 +1,119 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeagent
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	linuxproc "github.com/c9s/goprocinfo/linux"
+)
+
+// quotaIDCounterFile names the small counter file this agent bumps, under the export
+// root, to hand out unused project ids.
+const quotaIDCounterFile = ".quota-next-id"
+
+// quotaMountOptions returns the filesystem type and mount options linux reports for mp,
+// or "", "" if mp isn't a known mount point.
+func quotaMountOptions(mp string) (fsType string, options string) {
+	mps, err := linuxproc.ReadMounts("/proc/mounts")
+	if err != nil {
+		return "", ""
+	}
+	for _, m := range mps.Mounts {
+		if m.MountPoint == mp {
+			return m.FSType, m.Options
+		}
+	}
+	return "", ""
+}
+
+// quotaSupported reports whether mp is mounted on a filesystem xfs_quota can enforce
+// per-directory project quotas on. XFS always carries project quota accounting; ext4
+// only does when mounted with the prjquota (or project) option.
+func quotaSupported(mp string) bool {
+	fsType, options := quotaMountOptions(mp)
+	switch fsType {
+	case "xfs":
+		return true
+	case "ext4":
+		return strings.Contains(options, "prjquota") || strings.Contains(options, "project")
+	}
+	return false
+}
+
+// allocateProjectID picks the next unused project id for the export rooted at mp by
+// bumping a small counter file kept alongside the provisioned directories, holding an
+// flock on it for the read-modify-write so concurrent Provision calls (including from
+// other node-agent replicas sharing this export) can't hand out the same id twice.
+func allocateProjectID(mp string) (uint32, error) {
+	counterPath := filepath.Join(mp, quotaIDCounterFile)
+	f, err := os.OpenFile(counterPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("unable to open quota id counter: %v", err)
+	}
+	defer f.Close()
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		return 0, fmt.Errorf("unable to lock quota id counter: %v", err)
+	}
+	defer syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+
+	var id uint64 = 1
+	if b, err := ioutil.ReadAll(f); err == nil {
+		if n, err := strconv.ParseUint(strings.TrimSpace(string(b)), 10, 32); err == nil {
+			id = n + 1
+		}
+	}
+	if err := f.Truncate(0); err != nil {
+		return 0, fmt.Errorf("unable to persist quota id counter: %v", err)
+	}
+	if _, err := f.WriteAt([]byte(strconv.FormatUint(id, 10)), 0); err != nil {
+		return 0, fmt.Errorf("unable to persist quota id counter: %v", err)
+	}
+	return uint32(id), nil
+}
+
+// setProjectQuota assigns dir to project id and caps it at hardBytes via xfs_quota,
+// which manages project quotas on both XFS and quota-enabled ext4 filesystems.
+func setProjectQuota(mp string, dir string, id uint32, hardBytes int64) error {
+	project := fmt.Sprintf("project -s -p %s %d", dir, id)
+	if out, err := exec.Command("xfs_quota", "-x", "-c", project, mp).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set project %d on %s: %v: %s", id, dir, err, out)
+	}
+	limit := fmt.Sprintf("limit -p bhard=%d %d", hardBytes, id)
+	if out, err := exec.Command("xfs_quota", "-x", "-c", limit, mp).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set quota limit for project %d: %v: %s", id, err, out)
+	}
+	return nil
+}
+
+// clearProjectQuota drops the hard limit previously set for id. The id itself is not
+// handed out again: allocateProjectID only ever counts upward.
+func clearProjectQuota(mp string, id uint32) error {
+	limit := fmt.Sprintf("limit -p bhard=0 %d", id)
+	if out, err := exec.Command("xfs_quota", "-x", "-c", limit, mp).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to clear quota limit for project %d: %v: %s", id, err, out)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodeagent implements the node-local half of the nfs-client
+// provisioner split: the gRPC service that actually mounts NFS exports and
+// manipulates directories under them. It runs in the nfs-node-agent
+// DaemonSet, which is privileged so the nfs-client-provisioner controller
+// doesn't have to be.
+package nodeagent
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	context "golang.org/x/net/context"
+
+	linuxproc "github.com/c9s/goprocinfo/linux"
+)
+
+// mountPath is where exports are mounted on the node, matching the path the
+// single-binary provisioner used before the controller/agent split.
+const mountPath = "/persistentvolumes"
+
+// Server implements NodeAgentServer against the local filesystem.
+type Server struct{}
+
+// NewServer returns a Server ready to be registered with RegisterNodeAgentServer.
+func NewServer() *Server {
+	return &Server{}
+}
+
+func isMounted(mp string) bool {
+	mps, err := linuxproc.ReadMounts("/proc/mounts")
+	if err != nil {
+		return false
+	}
+	for _, m := range mps.Mounts {
+		if m.MountPoint == mp {
+			return true
+		}
+	}
+	return false
+}
+
+func mountPoint(server string, path string) string {
+	return fmt.Sprintf("%s/%s/%s", mountPath, server, url.QueryEscape(path))
+}
+
+// EnsureMount mounts server:path under mountPath if it isn't already mounted,
+// and returns the resulting local mount point.
+func (s *Server) EnsureMount(ctx context.Context, req *EnsureMountRequest) (*EnsureMountReply, error) {
+	mp := mountPoint(req.Server, req.Path)
+	if !isMounted(mp) {
+		if err := os.MkdirAll(mp, 0777); err != nil {
+			return nil, err
+		}
+		// has to run in a privileged container
+		cmd := exec.Command("mount", fmt.Sprintf("%s:%s", req.Server, req.Path), mp)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return nil, fmt.Errorf("mount %s:%s %s: %v: %s", req.Server, req.Path, mp, err, out)
+		}
+	}
+	return &EnsureMountReply{MountPoint: mp}, nil
+}
+
+// ReleaseMount unmounts a mount point previously returned by EnsureMount.
+func (s *Server) ReleaseMount(ctx context.Context, req *ReleaseMountRequest) (*ReleaseMountReply, error) {
+	if !isMounted(req.MountPoint) {
+		return &ReleaseMountReply{}, nil
+	}
+	cmd := exec.Command("umount", req.MountPoint)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("umount %s: %v: %s", req.MountPoint, err, out)
+	}
+	return &ReleaseMountReply{}, nil
+}
+
+// Mkdir creates path (and any missing parents) with the given mode.
+func (s *Server) Mkdir(ctx context.Context, req *MkdirRequest) (*MkdirReply, error) {
+	if err := os.MkdirAll(req.Path, os.FileMode(req.Mode)); err != nil {
+		return nil, err
+	}
+	return &MkdirReply{}, nil
+}
+
+// Rename moves oldPath to newPath. When newPath is being archived (its basename carries
+// ArchivePrefix), it also drops an archiveMeta sidecar recording the rename time, so the
+// archive TTL reaper can expire it correctly even across agent restarts.
+func (s *Server) Rename(ctx context.Context, req *RenameRequest) (*RenameReply, error) {
+	if err := os.Rename(req.OldPath, req.NewPath); err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(filepath.Base(req.NewPath), ArchivePrefix) {
+		writeArchiveMeta(req.NewPath)
+	}
+	return &RenameReply{}, nil
+}
+
+// RemoveAll recursively removes path.
+func (s *Server) RemoveAll(ctx context.Context, req *RemoveAllRequest) (*RemoveAllReply, error) {
+	if err := os.RemoveAll(req.Path); err != nil {
+		return nil, err
+	}
+	return &RemoveAllReply{}, nil
+}
+
+// Clone makes dst a point-in-time copy of src, used both to snapshot a provisioned PV
+// directory and to restore one by cloning a snapshot's path into a new PV directory. It
+// uses req.Technique if set, otherwise picks the cheapest technique src's filesystem
+// supports, and always drops a snapshotMeta sidecar in dst recording what it used.
+func (s *Server) Clone(ctx context.Context, req *CloneRequest) (*CloneReply, error) {
+	technique := req.Technique
+	if technique == "" {
+		technique = cloneTechniqueFor(req.Src)
+	}
+	if err := clone(req.Src, req.Dst, technique); err != nil {
+		return nil, err
+	}
+	writeSnapshotMeta(req.Dst, req.Src, technique)
+	return &CloneReply{Backend: technique}, nil
+}
+
+// Stat reports whether path exists and, if so, its size/mtime/directory-ness.
+func (s *Server) Stat(ctx context.Context, req *StatRequest) (*StatReply, error) {
+	info, err := os.Stat(req.Path)
+	if os.IsNotExist(err) {
+		return &StatReply{Exists: false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &StatReply{
+		Exists:      true,
+		IsDir:       info.IsDir(),
+		SizeBytes:   info.Size(),
+		ModTimeUnix: info.ModTime().Unix(),
+	}, nil
+}
+
+// QuotaSupported reports whether mountPoint's filesystem can enforce a per-directory
+// project quota.
+func (s *Server) QuotaSupported(ctx context.Context, req *QuotaSupportedRequest) (*QuotaSupportedReply, error) {
+	return &QuotaSupportedReply{Supported: quotaSupported(req.MountPoint)}, nil
+}
+
+// SetQuota allocates a project id on mountPoint's filesystem, assigns it to dir, and caps
+// it at hardBytes.
+func (s *Server) SetQuota(ctx context.Context, req *SetQuotaRequest) (*SetQuotaReply, error) {
+	id, err := allocateProjectID(req.MountPoint)
+	if err != nil {
+		return nil, fmt.Errorf("unable to allocate a quota project id: %v", err)
+	}
+	if err := setProjectQuota(req.MountPoint, req.Dir, id, req.HardBytes); err != nil {
+		return nil, err
+	}
+	return &SetQuotaReply{ProjectId: id}, nil
+}
+
+// ClearQuota drops the hard limit previously set for req.ProjectId. The id is not handed
+// out again, since project ids come from a counter that only increases.
+func (s *Server) ClearQuota(ctx context.Context, req *ClearQuotaRequest) (*ClearQuotaReply, error) {
+	if err := clearProjectQuota(req.MountPoint, req.ProjectId); err != nil {
+		return nil, err
+	}
+	return &ClearQuotaReply{}, nil
+}
+
+// Usage statfs(2)s req.Path's filesystem and walks req.Path to report space and inode
+// metrics for it, the way MetricsCollector used to do directly before the controller
+// stopped having exports mounted locally.
+func (s *Server) Usage(ctx context.Context, req *UsageRequest) (*UsageReply, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(req.Path, &stat); err != nil {
+		return nil, fmt.Errorf("unable to statfs %s: %v", req.Path, err)
+	}
+	used, err := duBytes(req.Path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute used bytes under %s: %v", req.Path, err)
+	}
+	blockSize := uint64(stat.Bsize)
+	return &UsageReply{
+		CapacityBytes:  int64(stat.Blocks * blockSize),
+		AvailableBytes: int64(stat.Bavail * blockSize),
+		InodesFree:     int64(stat.Ffree),
+		UsedBytes:      used,
+	}, nil
+}
+
+// duBytes walks dir and sums file sizes, mirroring `du`'s apparent-size accounting
+// closely enough for an operational signal; it isn't billing-grade precision.
+func duBytes(dir string) (int64, error) {
+	var total int64
+	err := filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total, err
+}
+
+var _ NodeAgentServer = &Server{}
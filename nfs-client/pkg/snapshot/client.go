@@ -0,0 +1,97 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+)
+
+func init() {
+	AddToScheme(scheme.Scheme)
+}
+
+// NewRESTClient returns a rest.Interface scoped to the NFSSnapshot CRD's group/version.
+// There is no generated clientset for this CRD (no deepcopy-gen/client-gen wired up in
+// this tree), so callers use client-go's generic REST verbs directly, the same way the
+// apiserver's own aggregated APIs are accessed before a typed clientset exists for them.
+func NewRESTClient(config *rest.Config) (rest.Interface, error) {
+	cfg := *config
+	cfg.GroupVersion = &SchemeGroupVersion
+	cfg.APIPath = "/apis"
+	cfg.NegotiatedSerializer = serializer.DirectCodecFactory{CodecFactory: scheme.Codecs}
+	cfg.UserAgent = rest.DefaultKubernetesUserAgent()
+	return rest.RESTClientFor(&cfg)
+}
+
+// List returns every NFSSnapshot in namespace ("" for all namespaces).
+func List(client rest.Interface, namespace string) (*NFSSnapshotList, error) {
+	result := &NFSSnapshotList{}
+	err := client.Get().Namespace(namespace).Resource(plural).Do().Into(result)
+	return result, err
+}
+
+// UpdateStatus persists snap's current Status.
+func UpdateStatus(client rest.Interface, snap *NFSSnapshot) (*NFSSnapshot, error) {
+	result := &NFSSnapshot{}
+	err := client.Put().
+		Namespace(snap.Namespace).
+		Resource(plural).
+		Name(snap.Name).
+		SubResource("status").
+		Body(snap).
+		Do().
+		Into(result)
+	return result, err
+}
+
+// RemoveFinalizer drops finalizer from snap and persists the result.
+func RemoveFinalizer(client rest.Interface, snap *NFSSnapshot, finalizer string) error {
+	kept := snap.Finalizers[:0]
+	for _, f := range snap.Finalizers {
+		if f != finalizer {
+			kept = append(kept, f)
+		}
+	}
+	snap.Finalizers = kept
+	return client.Put().
+		Namespace(snap.Namespace).
+		Resource(plural).
+		Name(snap.Name).
+		Body(snap).
+		Do().
+		Into(&NFSSnapshot{})
+}
+
+// AddFinalizer adds finalizer to snap (if not already present) and persists the result,
+// refreshing snap from the server's reply so a following UpdateStatus isn't stale.
+func AddFinalizer(client rest.Interface, snap *NFSSnapshot, finalizer string) error {
+	for _, f := range snap.Finalizers {
+		if f == finalizer {
+			return nil
+		}
+	}
+	snap.Finalizers = append(snap.Finalizers, finalizer)
+	return client.Put().
+		Namespace(snap.Namespace).
+		Resource(plural).
+		Name(snap.Name).
+		Body(snap).
+		Do().
+		Into(snap)
+}
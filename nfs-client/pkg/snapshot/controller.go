@@ -0,0 +1,168 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package snapshot
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/golang/glog"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubernetes-incubator/external-storage/nfs-client/pkg/nodeagent"
+)
+
+// finalizer is added to an NFSSnapshot while its cloned directory still exists, so
+// Controller can clean it up before the object is actually removed from the API server.
+const finalizer = "nfs-client-provisioner/snapshot-cleanup"
+
+// ClassTechniques maps an NFSSnapshot's spec.snapshotClass name onto the clone
+// technique it forces (one of the nodeagent CloneBackend constants). A class with no
+// entry here (including the empty class) auto-detects a technique from the source
+// export's filesystem, which is the right default for clusters that don't care. No
+// StorageClass parameter selects a class: restores (restoreFrom) always auto-detect.
+var ClassTechniques = map[string]string{
+	"btrfs": nodeagent.CloneBackendBtrfs,
+	"zfs":   nodeagent.CloneBackendZFS,
+	"cp":    nodeagent.CloneBackendReflink,
+	"rsync": nodeagent.CloneBackendRsync,
+}
+
+// Controller reconciles NFSSnapshot objects by cloning (and later removing) the
+// directory a provisioned NFS PV is backed by. It polls rather than watches, matching
+// the reaper in pkg/nodeagent: the object volume here is low enough that a shared
+// informer's bookkeeping isn't worth it.
+type Controller struct {
+	client    rest.Interface
+	k8sClient kubernetes.Interface
+	agent     *nodeagent.Client
+}
+
+// NewController returns a Controller that reconciles NFSSnapshots found through client,
+// resolving their source PVs through k8sClient and performing clones through agent.
+func NewController(client rest.Interface, k8sClient kubernetes.Interface, agent *nodeagent.Client) *Controller {
+	return &Controller{client: client, k8sClient: k8sClient, agent: agent}
+}
+
+// Run polls for NFSSnapshots to reconcile every period until stopCh closes.
+func (c *Controller) Run(period time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.reconcileAll()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+func (c *Controller) reconcileAll() {
+	list, err := List(c.client, metav1.NamespaceAll)
+	if err != nil {
+		glog.Warningf("unable to list NFSSnapshots: %v", err)
+		return
+	}
+	for i := range list.Items {
+		snap := &list.Items[i]
+		if err := c.reconcile(snap); err != nil {
+			glog.Warningf("error reconciling NFSSnapshot %s/%s: %v", snap.Namespace, snap.Name, err)
+		}
+	}
+}
+
+func (c *Controller) reconcile(snap *NFSSnapshot) error {
+	if snap.DeletionTimestamp != nil {
+		return c.reconcileDelete(snap)
+	}
+	if snap.Status.Phase != "" {
+		return nil
+	}
+	if err := AddFinalizer(c.client, snap, finalizer); err != nil {
+		return fmt.Errorf("unable to add finalizer: %v", err)
+	}
+
+	dst, backend, err := c.cloneSnapshot(snap)
+	if err != nil {
+		snap.Status.Phase = NFSSnapshotFailed
+		snap.Status.Error = err.Error()
+		if _, serr := UpdateStatus(c.client, snap); serr != nil {
+			glog.Warningf("unable to record failure status for NFSSnapshot %s/%s: %v", snap.Namespace, snap.Name, serr)
+		}
+		return err
+	}
+	snap.Status.Phase = NFSSnapshotReady
+	snap.Status.Path = dst
+	snap.Status.Backend = backend
+	_, err = UpdateStatus(c.client, snap)
+	return err
+}
+
+func (c *Controller) reconcileDelete(snap *NFSSnapshot) error {
+	hasFinalizer := false
+	for _, f := range snap.Finalizers {
+		if f == finalizer {
+			hasFinalizer = true
+		}
+	}
+	if !hasFinalizer {
+		return nil
+	}
+	if snap.Status.Path != "" {
+		if err := c.agent.RemoveAll(snap.Status.Path); err != nil {
+			return fmt.Errorf("unable to remove snapshot directory %s: %v", snap.Status.Path, err)
+		}
+	}
+	return RemoveFinalizer(c.client, snap, finalizer)
+}
+
+// cloneSnapshot resolves snap's source PV to a node-local path and clones it into a new
+// directory under the same export, returning the directory and the clone technique used.
+func (c *Controller) cloneSnapshot(snap *NFSSnapshot) (string, string, error) {
+	pv, err := c.k8sClient.CoreV1().PersistentVolumes().Get(snap.Spec.SourcePVName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("unable to get source PV %s: %v", snap.Spec.SourcePVName, err)
+	}
+	if pv.Spec.NFS == nil {
+		return "", "", fmt.Errorf("PV %s was not provisioned by the NFS backend", pv.Name)
+	}
+	server := pv.Spec.NFS.Server
+	nfsPath := path.Dir(pv.Spec.NFS.Path)
+	mp, err := c.agent.EnsureMount(server, nfsPath)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to mount %s:%s: %v", server, nfsPath, err)
+	}
+
+	src := filepath.Join(mp, path.Base(pv.Spec.NFS.Path))
+	if err := c.agent.Mkdir(filepath.Join(mp, ".snapshots"), 0700); err != nil {
+		return "", "", fmt.Errorf("unable to create snapshot staging directory: %v", err)
+	}
+	dst := filepath.Join(mp, ".snapshots", snap.Name)
+
+	technique := ClassTechniques[snap.Spec.SnapshotClass]
+	backend, err := c.agent.Clone(src, dst, technique)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to clone %s to %s: %v", src, dst, err)
+	}
+	return dst, backend, nil
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package snapshot adds point-in-time copies of provisioned PV directories on top of
+// pkg/backend's NFS backend. Clusters with the CSI external-snapshotter installed should
+// prefer its VolumeSnapshot/VolumeSnapshotContent objects; this package instead defines a
+// small NFSSnapshot CustomResourceDefinition for clusters without it, since there is no
+// vendored client for the CSI snapshot CRDs in this tree to watch them with.
+package snapshot
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// GroupName is the API group NFSSnapshot is registered under.
+	GroupName = "nfs.external-storage.io"
+	// Version is the (alpha, single-version) API version of the group.
+	Version = "v1alpha1"
+)
+
+// SchemeGroupVersion is the GroupVersion NFSSnapshot objects are served at.
+var SchemeGroupVersion = schema.GroupVersion{Group: GroupName, Version: Version}
+
+var (
+	// SchemeBuilder collects the funcs that add NFSSnapshot's types to a runtime.Scheme.
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+	// AddToScheme adds NFSSnapshot's types to a runtime.Scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion, &NFSSnapshot{}, &NFSSnapshotList{})
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
+
+// NFSSnapshotPhase is the lifecycle phase of an NFSSnapshot.
+type NFSSnapshotPhase string
+
+const (
+	// NFSSnapshotPending means the controller hasn't processed this object yet.
+	NFSSnapshotPending NFSSnapshotPhase = "Pending"
+	// NFSSnapshotReady means Path/Backend are populated and usable as a restore source.
+	NFSSnapshotReady NFSSnapshotPhase = "Ready"
+	// NFSSnapshotFailed means the clone attempt errored; Error holds why.
+	NFSSnapshotFailed NFSSnapshotPhase = "Failed"
+)
+
+// NFSSnapshot is a point-in-time copy of a provisioned PV's directory. It's the fallback
+// for clusters without the CSI external-snapshotter's VolumeSnapshot/VolumeSnapshotContent
+// CRDs installed.
+type NFSSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NFSSnapshotSpec   `json:"spec"`
+	Status NFSSnapshotStatus `json:"status,omitempty"`
+}
+
+// NFSSnapshotSpec is the desired state of an NFSSnapshot, set by whoever creates it.
+type NFSSnapshotSpec struct {
+	// SourcePVName is the PersistentVolume to snapshot. It must have been provisioned by
+	// the NFS backend; snapshotting any other backend isn't supported.
+	SourcePVName string `json:"sourcePVName"`
+	// SnapshotClass optionally maps onto a forced clone technique (see
+	// ClassTechniques); empty auto-detects one from the source export's filesystem.
+	SnapshotClass string `json:"snapshotClass,omitempty"`
+}
+
+// NFSSnapshotStatus is the observed state of an NFSSnapshot, set by the controller.
+type NFSSnapshotStatus struct {
+	Phase NFSSnapshotPhase `json:"phase,omitempty"`
+	// Path is the node-local directory the snapshot was cloned into, usable as the
+	// source of a restore once Phase is NFSSnapshotReady.
+	Path string `json:"path,omitempty"`
+	// Backend is the clone technique the agent actually used (see the nodeagent
+	// CloneBackend constants).
+	Backend string `json:"backend,omitempty"`
+	// Error explains why Phase is NFSSnapshotFailed.
+	Error string `json:"error,omitempty"`
+}
+
+// NFSSnapshotList is a list of NFSSnapshots.
+type NFSSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []NFSSnapshot `json:"items"`
+}
+
+// DeepCopyObject implements runtime.Object. Hand-written since this tree has no
+// deepcopy-gen wired up to generate it.
+func (in *NFSSnapshot) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSSnapshot)
+	*out = *in
+	out.ObjectMeta = *in.ObjectMeta.DeepCopy()
+	return out
+}
+
+// DeepCopyObject implements runtime.Object. Hand-written since this tree has no
+// deepcopy-gen wired up to generate it.
+func (in *NFSSnapshotList) DeepCopyObject() runtime.Object {
+	if in == nil {
+		return nil
+	}
+	out := new(NFSSnapshotList)
+	*out = *in
+	out.Items = make([]NFSSnapshot, len(in.Items))
+	for i := range in.Items {
+		out.Items[i] = *in.Items[i].DeepCopyObject().(*NFSSnapshot)
+	}
+	return out
+}